@@ -0,0 +1,71 @@
+// Package cacheopts builds the cache options used by the controller manager.
+package cacheopts
+
+import (
+    "errors"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    "k8s.io/apimachinery/pkg/fields"
+    "sigs.k8s.io/controller-runtime/pkg/cache"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Build returns the manager cache options. In node-local mode the pod cache is
+// restricted to pods scheduled on nodeName, which must then be set. Other
+// object types are still cached cluster-wide. An empty namespaces list
+// watches all namespaces. Every cached object is passed through
+// StripManagedFields.
+func Build(nodeLocal bool, nodeName string, namespaces []string) (cache.Options, error) {
+    opts := cache.Options{
+        Namespaces:       namespaces,
+        DefaultTransform: StripManagedFields,
+    }
+    if nodeLocal {
+        if nodeName == "" {
+            return cache.Options{}, errors.New("node-local mode requires NODE_NAME to be set")
+        }
+        opts.ByObject = map[client.Object]cache.ByObject{
+            // A ByObject entry overrides DefaultTransform, so set it again.
+            &corev1.Pod{}: {
+                Field:     fields.OneTermEqualSelector("spec.nodeName", nodeName),
+                Transform: StripManagedFields,
+            },
+        }
+    }
+    return opts, nil
+}
+
+// StripManagedFields drops managedFields from objects before they are stored
+// in the cache. The controller never reads them and they make up a large part
+// of every cached pod. Labels, annotations, spec and status are kept as-is:
+// status subfields are deliberately not pruned, since the reconciler that
+// decides which of them it reads lives outside this repository.
+func StripManagedFields(obj interface{}) (interface{}, error) {
+    accessor, err := meta.Accessor(obj)
+    if err != nil {
+        // Tombstones and other non-object values are passed through untouched.
+        return obj, nil
+    }
+    accessor.SetManagedFields(nil)
+    return obj, nil
+}
+
+// SplitNamespaces parses a comma-separated namespace list, ignoring blanks and
+// duplicates. It returns nil for an empty list so the cache watches all
+// namespaces. Duplicates are dropped because the multi-namespace cache builds
+// one informer set per entry.
+func SplitNamespaces(value string) []string {
+    var namespaces []string
+    seen := map[string]bool{}
+    for _, ns := range strings.Split(value, ",") {
+        ns = strings.TrimSpace(ns)
+        if ns == "" || seen[ns] {
+            continue
+        }
+        seen[ns] = true
+        namespaces = append(namespaces, ns)
+    }
+    return namespaces
+}
@@ -0,0 +1,173 @@
+package cacheopts
+
+import (
+    "reflect"
+    "testing"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestBuildNodeLocal(t *testing.T) {
+    tests := []struct {
+        name      string
+        nodeLocal bool
+        nodeName  string
+        wantField string
+        wantErr   bool
+    }{
+        {name: "cluster-wide", nodeLocal: false, nodeName: "node-a"},
+        {name: "node-local", nodeLocal: true, nodeName: "node-a", wantField: "spec.nodeName=node-a"},
+        {name: "node-local without NODE_NAME", nodeLocal: true, nodeName: "", wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            opts, err := Build(tt.nodeLocal, tt.nodeName, nil)
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatal("expected an error, got nil")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("unexpected error: %v", err)
+            }
+
+            if tt.wantField == "" {
+                if len(opts.ByObject) != 0 {
+                    t.Fatalf("expected no per-object options, got %v", opts.ByObject)
+                }
+                return
+            }
+            var found bool
+            for obj, byObject := range opts.ByObject {
+                if _, ok := obj.(*corev1.Pod); !ok {
+                    t.Fatalf("unexpected per-object options for %T", obj)
+                }
+                found = true
+                if byObject.Field == nil {
+                    t.Fatal("expected a field selector on the pod cache")
+                }
+                if got := byObject.Field.String(); got != tt.wantField {
+                    t.Errorf("pod field selector = %q, want %q", got, tt.wantField)
+                }
+            }
+            if !found {
+                t.Fatal("expected per-object options for pods")
+            }
+        })
+    }
+}
+
+func TestBuildSetsTransform(t *testing.T) {
+    opts, err := Build(true, "node-a", nil)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if opts.DefaultTransform == nil {
+        t.Error("expected a default transform")
+    }
+    for obj, byObject := range opts.ByObject {
+        if byObject.Transform == nil {
+            t.Errorf("expected a transform for %T", obj)
+        }
+    }
+}
+
+func TestStripManagedFields(t *testing.T) {
+    pod := &corev1.Pod{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:        "web-0",
+            Namespace:   "default",
+            UID:         types.UID("uid-1"),
+            Labels:      map[string]string{"app": "web"},
+            Annotations: map[string]string{"team": "payments"},
+            ManagedFields: []metav1.ManagedFieldsEntry{
+                {Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply},
+            },
+        },
+        Spec:   corev1.PodSpec{NodeName: "node-a"},
+        Status: corev1.PodStatus{Phase: corev1.PodRunning},
+    }
+    want := pod.DeepCopy()
+    want.ManagedFields = nil
+
+    out, err := StripManagedFields(pod)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    got, ok := out.(*corev1.Pod)
+    if !ok {
+        t.Fatalf("expected *corev1.Pod, got %T", out)
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("transformed pod = %+v, want %+v", got, want)
+    }
+}
+
+func TestStripManagedFieldsTombstone(t *testing.T) {
+    tombstone := toolscache.DeletedFinalStateUnknown{
+        Key: "default/web-0",
+        Obj: &corev1.Pod{
+            ObjectMeta: metav1.ObjectMeta{
+                Name:          "web-0",
+                ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+            },
+        },
+    }
+    want := toolscache.DeletedFinalStateUnknown{
+        Key: tombstone.Key,
+        Obj: tombstone.Obj.(*corev1.Pod).DeepCopy(),
+    }
+
+    out, err := StripManagedFields(tombstone)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if !reflect.DeepEqual(out, want) {
+        t.Errorf("tombstone = %+v, want it unchanged", out)
+    }
+}
+
+func TestSplitNamespaces(t *testing.T) {
+    tests := []struct {
+        name  string
+        value string
+        want  []string
+    }{
+        {name: "empty", value: "", want: nil},
+        {name: "only blanks", value: " , ,", want: nil},
+        {name: "single", value: "team-a", want: []string{"team-a"}},
+        {name: "whitespace", value: " team-a ,team-b ", want: []string{"team-a", "team-b"}},
+        {name: "duplicates", value: "team-a,team-b,team-a, team-b", want: []string{"team-a", "team-b"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := SplitNamespaces(tt.value); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("SplitNamespaces(%q) = %#v, want %#v", tt.value, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestBuildNamespaces(t *testing.T) {
+    opts, err := Build(false, "", SplitNamespaces(""))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if opts.Namespaces != nil {
+        t.Errorf("expected nil namespaces for cluster-wide watch, got %#v", opts.Namespaces)
+    }
+
+    opts, err = Build(false, "", SplitNamespaces("team-a,team-a"))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if want := []string{"team-a"}; !reflect.DeepEqual(opts.Namespaces, want) {
+        t.Errorf("namespaces = %#v, want %#v", opts.Namespaces, want)
+    }
+}
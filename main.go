@@ -1,10 +1,10 @@
 package main
 
 import (
+    "errors"
     "flag"
     "os"
 
-    corev1 "k8s.io/api/core/v1"
     clientgoscheme "k8s.io/client-go/kubernetes/scheme"
     "k8s.io/client-go/tools/leaderelection/resourcelock"
     ctrl "sigs.k8s.io/controller-runtime"
@@ -13,6 +13,7 @@ import (
 
     myapiv1 "github.com/yourrepo/yourcontroller/api/v1"
     "github.com/yourrepo/yourcontroller/controllers"
+    "github.com/rockswe/K8s-PodConfigMapController/internal/cacheopts"
     "k8s.io/apimachinery/pkg/runtime"
     utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 )
@@ -31,9 +32,13 @@ func main() {
     var metricsAddr string
     var enableLeaderElection bool
     var probeAddr string
+    var nodeLocal bool
+    var watchNamespaces string
     flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
     flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
     flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+    flag.BoolVar(&nodeLocal, "node-local", false, "Only handle pods scheduled on the node named by NODE_NAME. Cannot be combined with --leader-elect.")
+    flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma-separated list of namespaces to watch. Watches all namespaces when empty.")
 
     opts := zap.Options{
         Development: true,
@@ -43,8 +48,23 @@ func main() {
 
     ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+    if nodeLocal && enableLeaderElection {
+        setupLog.Error(errors.New("--leader-elect cannot be combined with --node-local"), "invalid flags")
+        os.Exit(1)
+    }
+
+    cacheOpts, err := cacheopts.Build(nodeLocal, os.Getenv("NODE_NAME"), cacheopts.SplitNamespaces(watchNamespaces))
+    if err != nil {
+        setupLog.Error(err, "unable to build cache options")
+        os.Exit(1)
+    }
+    if nodeLocal {
+        setupLog.Info("running in node-local mode", "node", os.Getenv("NODE_NAME"))
+    }
+
     mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
         Scheme:                     scheme,
+        Cache:                      cacheOpts,
         MetricsBindAddress:         metricsAddr,
         Port:                       9443,
         HealthProbeBindAddress:     probeAddr,